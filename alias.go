@@ -0,0 +1,169 @@
+package pinned
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrDowngradeBlocked is returned by VersionManager.ParseWithCurrent when
+// resolving an alias (see below) would move the caller to a version older
+// than its current one.
+var ErrDowngradeBlocked = errors.New("pinned: resolved alias would downgrade below current version")
+
+// Alias version strings recognized by VersionManager.Parse and
+// VersionManager.ParseWithCurrent, modeled on go get's module query
+// semantics.
+const (
+	aliasLatest  = "latest"
+	aliasPatch   = "patch"
+	aliasUpgrade = "upgrade"
+)
+
+// PatchGrouper is implemented by VersionSchemes that support the "patch"
+// alias: it reports whether two versions belong to the same patch-release
+// series (e.g. the same major.minor under SemVerScheme, or the same year
+// under DateScheme).
+type PatchGrouper interface {
+	SamePatchGroup(a, b Comparable) bool
+}
+
+// SamePatchGroup implements PatchGrouper, grouping dates by year.
+func (DateScheme) SamePatchGroup(a, b Comparable) bool {
+	return a.(time.Time).Year() == b.(time.Time).Year()
+}
+
+// SamePatchGroup implements PatchGrouper, grouping by major.minor.
+func (SemVerScheme) SamePatchGroup(a, b Comparable) bool {
+	va, vb := a.(semVer), b.(semVer)
+	return va.Major == vb.Major && va.Minor == vb.Minor
+}
+
+// resolveCandidate resolves a single version string supplied in a request,
+// handling both concrete versions (including partial ones, via
+// VersionManager.resolve) and the latest/patch/upgrade aliases.
+func (vm *VersionManager) resolveCandidate(s string, current *Version) (*Version, error) {
+	switch s {
+	case aliasLatest:
+		return vm.resolveLatestAlias(current)
+	case aliasPatch:
+		return vm.resolvePatchAlias(current)
+	case aliasUpgrade:
+		return vm.resolveUpgradeAlias(current)
+	}
+
+	v := vm.resolve(s)
+	if v == nil {
+		return nil, ErrInvalidVersion
+	}
+	return v, nil
+}
+
+// resolveLatestAlias resolves "latest" to the newest non-deprecated
+// version.
+func (vm *VersionManager) resolveLatestAlias(current *Version) (*Version, error) {
+	latest := vm.latestNonDeprecated()
+	if latest == nil {
+		return nil, ErrInvalidVersion
+	}
+	return vm.checkDowngrade(current, latest)
+}
+
+// resolvePatchAlias resolves "patch" to the newest non-deprecated version
+// sharing current's patch group (see PatchGrouper).
+func (vm *VersionManager) resolvePatchAlias(current *Version) (*Version, error) {
+	if current == nil {
+		return nil, ErrInvalidVersion
+	}
+
+	scheme := vm.scheme()
+	grouper, ok := scheme.(PatchGrouper)
+	if !ok {
+		return nil, ErrInvalidVersion
+	}
+	currentComparable, err := scheme.Parse(current.Date)
+	if err != nil {
+		return nil, ErrInvalidVersion
+	}
+
+	var best *Version
+	var bestComparable Comparable
+	for _, v := range vm.versions {
+		if v.Deprecated {
+			continue
+		}
+		c, err := scheme.Parse(v.Date)
+		if err != nil {
+			continue
+		}
+		if !grouper.SamePatchGroup(c, currentComparable) {
+			continue
+		}
+		if best == nil || scheme.Compare(c, bestComparable) > 0 {
+			best, bestComparable = v, c
+		}
+	}
+	if best == nil {
+		return nil, ErrInvalidVersion
+	}
+	return vm.checkDowngrade(current, best)
+}
+
+// resolveUpgradeAlias resolves "upgrade" to the newest non-deprecated
+// version, unless current is already newer, in which case current is
+// returned unchanged.
+func (vm *VersionManager) resolveUpgradeAlias(current *Version) (*Version, error) {
+	latest := vm.latestNonDeprecated()
+	if latest == nil {
+		return nil, ErrInvalidVersion
+	}
+	if current == nil {
+		return latest, nil
+	}
+
+	scheme := vm.scheme()
+	currentComparable, err := scheme.Parse(current.Date)
+	if err != nil {
+		return nil, ErrInvalidVersion
+	}
+	latestComparable, err := scheme.Parse(latest.Date)
+	if err != nil {
+		return nil, ErrInvalidVersion
+	}
+	if scheme.Compare(currentComparable, latestComparable) >= 0 {
+		return current, nil
+	}
+	return latest, nil
+}
+
+// checkDowngrade returns resolved, or ErrDowngradeBlocked if current is
+// non-nil and resolved is older than current.
+func (vm *VersionManager) checkDowngrade(current, resolved *Version) (*Version, error) {
+	if current == nil {
+		return resolved, nil
+	}
+
+	scheme := vm.scheme()
+	currentComparable, err := scheme.Parse(current.Date)
+	if err != nil {
+		return resolved, nil
+	}
+	resolvedComparable, err := scheme.Parse(resolved.Date)
+	if err != nil {
+		return resolved, nil
+	}
+	if scheme.Compare(resolvedComparable, currentComparable) < 0 {
+		return nil, ErrDowngradeBlocked
+	}
+	return resolved, nil
+}
+
+// latestNonDeprecated returns the newest registered version that is not
+// deprecated, or nil if none qualify.
+func (vm *VersionManager) latestNonDeprecated() *Version {
+	for _, v := range vm.versions {
+		if !v.Deprecated {
+			return v
+		}
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package pinned
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newAliasTestManager() *VersionManager {
+	vm := &VersionManager{}
+	vm.Add(&Version{Date: "2016-01-02"})
+	vm.Add(&Version{Date: "2017-01-02"})
+	vm.Add(&Version{Date: "2018-06-01"})
+	vm.Add(&Version{Date: "2018-12-01", Deprecated: true})
+	return vm
+}
+
+func TestVersionManagerParseLatestAlias(t *testing.T) {
+	vm := newAliasTestManager()
+
+	req := httptest.NewRequest(http.MethodGet, "/users?v=latest", nil)
+	v, err := vm.Parse(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if v.Date != "2018-06-01" {
+		t.Fatalf("expected latest non-deprecated version, got %s", v.Date)
+	}
+}
+
+func TestVersionManagerParsePatchAlias(t *testing.T) {
+	vm := newAliasTestManager()
+	vm.Add(&Version{Date: "2017-08-01"})
+
+	// "patch" with no current version is invalid.
+	req := httptest.NewRequest(http.MethodGet, "/users?v=patch", nil)
+	if _, err := vm.Parse(req); err != ErrInvalidVersion {
+		t.Fatalf("expected ErrInvalidVersion, got %s", err)
+	}
+
+	current := &Version{Date: "2017-01-02"}
+	v, err := vm.ParseWithCurrent(req, current)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if v.Date != "2017-08-01" {
+		t.Fatalf("expected newest version in current's year, got %s", v.Date)
+	}
+}
+
+func TestVersionManagerParseUpgradeAlias(t *testing.T) {
+	vm := newAliasTestManager()
+
+	req := httptest.NewRequest(http.MethodGet, "/users?v=upgrade", nil)
+
+	// If current is already newer than latest, stay put.
+	current := &Version{Date: "2020-01-01"}
+	v, err := vm.ParseWithCurrent(req, current)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if v.Date != current.Date {
+		t.Fatalf("expected upgrade to return current version, got %s", v.Date)
+	}
+
+	// Otherwise, upgrade to latest.
+	current = &Version{Date: "2016-01-02"}
+	v, err = vm.ParseWithCurrent(req, current)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if v.Date != "2018-06-01" {
+		t.Fatalf("expected upgrade to resolve to latest, got %s", v.Date)
+	}
+}
+
+func TestVersionManagerParseDowngradeBlocked(t *testing.T) {
+	vm := &VersionManager{}
+	vm.Add(&Version{Date: "2017-01-02"})
+	vm.Add(&Version{Date: "2017-06-01", Deprecated: true})
+
+	req := httptest.NewRequest(http.MethodGet, "/users?v=latest", nil)
+	current := &Version{Date: "2017-06-01"}
+	_, err := vm.ParseWithCurrent(req, current)
+	if err != ErrDowngradeBlocked {
+		t.Fatalf("expected ErrDowngradeBlocked, got %s", err)
+	}
+}
@@ -0,0 +1,100 @@
+package pinned
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// Changelog returns a defensive copy of vm's registered versions, sorted
+// newest first, so callers can build their own changelog pages instead of
+// using ChangelogHandler.
+func (vm *VersionManager) Changelog() []*Version {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	versions := make([]*Version, len(vm.versions))
+	copy(versions, vm.versions)
+	return versions
+}
+
+// changelogEntry is the JSON representation of a single Version served by
+// ChangelogHandler.
+type changelogEntry struct {
+	Date       string            `json:"date"`
+	Deprecated bool              `json:"deprecated"`
+	Sunset     string            `json:"sunset,omitempty"`
+	Changes    []changelogChange `json:"changes"`
+}
+
+// changelogChange is the JSON representation of a single Change.
+type changelogChange struct {
+	Description string `json:"description"`
+}
+
+// changelogEntries converts vm's registered versions into their JSON/HTML
+// representation, newest first.
+func (vm *VersionManager) changelogEntries() []changelogEntry {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	entries := make([]changelogEntry, len(vm.versions))
+	for i, v := range vm.versions {
+		changes := make([]changelogChange, len(v.Changes))
+		for j, c := range v.Changes {
+			changes[j] = changelogChange{Description: c.Description}
+		}
+
+		entry := changelogEntry{
+			Date:       v.Date,
+			Deprecated: v.Deprecated,
+			Changes:    changes,
+		}
+		if !v.SunsetAt.IsZero() {
+			entry.Sunset = v.SunsetAt.Format(http.TimeFormat)
+		}
+		entries[i] = entry
+	}
+	return entries
+}
+
+// ChangelogHandler serves vm's registered versions, content-negotiated on
+// Accept: a JSON array of {date, deprecated, sunset, changes:[{description}]}
+// by default, or an HTML page modeled after gopkg.in's versions page when
+// Accept includes "text/html" — a getting-started snippet showing how to
+// pin a version via the Version header, followed by one section per
+// version listing its changes and deprecation status.
+func (vm *VersionManager) ChangelogHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		entries := vm.changelogEntries()
+
+		if strings.Contains(req.Header.Get("Accept"), "text/html") {
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			_ = changelogTemplate.Execute(w, entries)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(entries)
+	})
+}
+
+var changelogTemplate = template.Must(template.New("changelog").Parse(`<!DOCTYPE html>
+<html>
+<head><title>API Versions</title></head>
+<body>
+<h1>API Versions</h1>
+<p>Pin your integration to a version by sending it in the <code>Version</code> request header:</p>
+<pre>Version: {{if .}}{{(index . 0).Date}}{{end}}</pre>
+{{range .}}
+<h2 id="{{.Date}}"><a href="#{{.Date}}">{{.Date}}</a>{{if .Deprecated}} (deprecated){{end}}</h2>
+<ul>
+{{range .Changes}}<li>{{.Description}}</li>
+{{else}}<li>No changes.</li>
+{{end}}
+</ul>
+{{end}}
+</body>
+</html>
+`))
@@ -0,0 +1,69 @@
+package pinned
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVersionManagerChangelog(t *testing.T) {
+	vm := &VersionManager{}
+	vm.Add(&Version{Date: "2017-01-02"})
+	vm.Add(&Version{Date: "2018-01-02", Deprecated: true})
+
+	versions := vm.Changelog()
+	if len(versions) != 2 || versions[0].Date != "2018-01-02" || versions[1].Date != "2017-01-02" {
+		t.Fatalf("expected versions sorted descending, got %v", versions)
+	}
+
+	// Mutating the returned slice shouldn't affect the manager.
+	versions[0] = &Version{Date: "should-not-leak"}
+	if vm.Latest().Date != "2018-01-02" {
+		t.Fatal("Changelog should return a defensive copy")
+	}
+}
+
+func TestChangelogHandlerJSON(t *testing.T) {
+	vm := &VersionManager{}
+	vm.Add(&Version{
+		Date: "2017-01-02",
+		Changes: []*Change{
+			{Description: "Initial release."},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/changelog", nil)
+	req.Header.Set("Accept", "application/json")
+	rec := httptest.NewRecorder()
+	vm.ChangelogHandler().ServeHTTP(rec, req)
+
+	var entries []changelogEntry
+	if err := json.Unmarshal(rec.Body.Bytes(), &entries); err != nil {
+		t.Fatalf("expected valid JSON, got error: %s", err)
+	}
+	if len(entries) != 1 || entries[0].Date != "2017-01-02" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if entries[0].Changes[0].Description != "Initial release." {
+		t.Fatalf("expected change description, got %+v", entries[0].Changes)
+	}
+}
+
+func TestChangelogHandlerHTML(t *testing.T) {
+	vm := &VersionManager{}
+	vm.Add(&Version{Date: "2017-01-02"})
+
+	req := httptest.NewRequest(http.MethodGet, "/changelog", nil)
+	req.Header.Set("Accept", "text/html")
+	rec := httptest.NewRecorder()
+	vm.ChangelogHandler().ServeHTTP(rec, req)
+
+	if !strings.Contains(rec.Body.String(), "2017-01-02") {
+		t.Fatalf("expected HTML to list version, got %s", rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); !strings.Contains(ct, "text/html") {
+		t.Fatalf("expected text/html Content-Type, got %q", ct)
+	}
+}
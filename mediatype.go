@@ -0,0 +1,60 @@
+package pinned
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+)
+
+// MediaType configures the vendor media type VersionManager.Parse
+// recognizes in a request's Accept header, of the form
+// "application/vnd.<Vendor>+<Suffix>; version=<version>". It is the
+// lowest-precedence way to specify a version (see VersionManager.Parse),
+// useful for clients that prefer content negotiation over a custom header.
+type MediaType struct {
+	Vendor string
+	Suffix string
+}
+
+// parse extracts the "version" parameter from accept if it matches mt, e.g.
+// "application/vnd.acme+json; version=2018-01-02" yields ("2018-01-02",
+// true). It returns false if mt isn't configured, accept is empty or
+// doesn't match mt's vendor and suffix, or it carries no version
+// parameter.
+func (mt MediaType) parse(accept string) (string, bool) {
+	if mt.Vendor == "" || accept == "" {
+		return "", false
+	}
+
+	parsed, params, err := mime.ParseMediaType(accept)
+	if err != nil || parsed != mt.String() {
+		return "", false
+	}
+
+	v, ok := params["version"]
+	if !ok || v == "" {
+		return "", false
+	}
+	return v, true
+}
+
+// String returns mt's base media type, e.g. "application/vnd.acme+json".
+func (mt MediaType) String() string {
+	return fmt.Sprintf("application/vnd.%s+%s", mt.Vendor, mt.Suffix)
+}
+
+// contentType returns the full media type for v, e.g.
+// "application/vnd.acme+json; version=2018-01-02".
+func (mt MediaType) contentType(v *Version) string {
+	return fmt.Sprintf("%s; version=%s", mt.String(), v.Date)
+}
+
+// WriteContentType sets w's Content-Type header to the negotiated media
+// type for v, so that clients can cache-key responses on it. It is a no-op
+// if vm.MediaType isn't configured.
+func (vm *VersionManager) WriteContentType(w http.ResponseWriter, v *Version) {
+	if vm.MediaType.Vendor == "" {
+		return
+	}
+	w.Header().Set("Content-Type", vm.MediaType.contentType(v))
+}
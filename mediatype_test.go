@@ -0,0 +1,62 @@
+package pinned
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestVersionManagerParseAcceptHeader(t *testing.T) {
+	vm := &VersionManager{MediaType: MediaType{Vendor: "acme", Suffix: "json"}}
+	vm.Add(&Version{Date: "2017-01-02"})
+	vm.Add(&Version{Date: "2018-01-02"})
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("Accept", "application/vnd.acme+json; version=2017-01-02")
+	v, err := vm.Parse(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if v.Date != "2017-01-02" {
+		t.Fatalf("expected version from Accept header, got %s", v.Date)
+	}
+
+	// The Version header takes precedence over the Accept header.
+	req.Header.Set("Version", "2018-01-02")
+	v, err = vm.Parse(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if v.Date != "2018-01-02" {
+		t.Fatalf("expected Version header to win, got %s", v.Date)
+	}
+}
+
+func TestVersionManagerParseDefault(t *testing.T) {
+	vm := &VersionManager{}
+	vm.Add(&Version{Date: "2017-01-02"})
+	def := &Version{Date: "2018-01-02"}
+	vm.Default = def
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	v, err := vm.Parse(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if v != def {
+		t.Fatalf("expected Default version, got %v", v)
+	}
+}
+
+func TestWriteContentType(t *testing.T) {
+	vm := &VersionManager{MediaType: MediaType{Vendor: "acme", Suffix: "json"}}
+	v := &Version{Date: "2018-01-02"}
+
+	rec := httptest.NewRecorder()
+	vm.WriteContentType(rec, v)
+
+	want := "application/vnd.acme+json; version=2018-01-02"
+	if got := rec.Header().Get("Content-Type"); got != want {
+		t.Fatalf("expected Content-Type %q, got %q", want, got)
+	}
+}
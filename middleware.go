@@ -0,0 +1,83 @@
+package pinned
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+)
+
+// contextKey is an unexported type to prevent collisions with context keys
+// defined elsewhere.
+type contextKey int
+
+// versionContextKey is the key Middleware uses to store the resolved
+// Version on a request's context.
+const versionContextKey contextKey = 0
+
+// FromContext returns the Version stored on ctx by Middleware, or nil if
+// none is present.
+func FromContext(ctx context.Context) *Version {
+	v, _ := ctx.Value(versionContextKey).(*Version)
+	return v
+}
+
+// ChangelogURL returns the URL set by SetChangelogURL, used to populate the
+// Link header's rel="deprecation" target.
+func (vm *VersionManager) ChangelogURL() string {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+	return vm.changelogURL
+}
+
+// SetChangelogURL sets the URL used to populate the Link header's
+// rel="deprecation" target in Middleware.
+func (vm *VersionManager) SetChangelogURL(url string) {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	vm.changelogURL = url
+}
+
+// Middleware parses the request's version with vm.Parse and stores it on
+// the request context, retrievable with FromContext. If parsing fails, it
+// responds with 400 Bad Request and does not call next. If the resolved
+// version carries a DeprecatedAt or SunsetAt, it also sets the RFC 8594
+// Deprecation and Sunset response headers for whichever of the two is set,
+// and, if vm's ChangelogURL is set, a Link header pointing at it, before
+// calling next.
+func (vm *VersionManager) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		v, err := vm.Parse(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		vm.writeDeprecationHeaders(w, v)
+
+		ctx := context.WithValue(req.Context(), versionContextKey, v)
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// writeDeprecationHeaders sets the RFC 8594 Deprecation, Sunset, and Link
+// headers on w for v, if v has a DeprecatedAt or SunsetAt set. It takes
+// vm's read lock, since Deprecate mutates those same fields under the
+// write lock.
+func (vm *VersionManager) writeDeprecationHeaders(w http.ResponseWriter, v *Version) {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	if v.DeprecatedAt.IsZero() && v.SunsetAt.IsZero() {
+		return
+	}
+
+	if !v.DeprecatedAt.IsZero() {
+		w.Header().Set("Deprecation", v.DeprecatedAt.Format(dateFormat))
+	}
+	if !v.SunsetAt.IsZero() {
+		w.Header().Set("Sunset", v.SunsetAt.UTC().Format(http.TimeFormat))
+	}
+	if vm.changelogURL != "" {
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="deprecation"`, vm.changelogURL))
+	}
+}
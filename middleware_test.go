@@ -0,0 +1,60 @@
+package pinned
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMiddlewareSetsContextAndHeaders(t *testing.T) {
+	vm := &VersionManager{}
+	vm.SetChangelogURL("https://example.com/changelog")
+
+	sunset := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	vm.Add(&Version{
+		Date:         "2017-01-02",
+		DeprecatedAt: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+		SunsetAt:     sunset,
+	})
+
+	var gotFromContext *Version
+	handler := vm.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		gotFromContext = FromContext(req.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/users?v=2017-01-02", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if gotFromContext == nil || gotFromContext.Date != "2017-01-02" {
+		t.Fatalf("expected resolved version on context, got %v", gotFromContext)
+	}
+	if got := rec.Header().Get("Deprecation"); got != "2019-06-01" {
+		t.Fatalf("expected Deprecation header, got %q", got)
+	}
+	if got := rec.Header().Get("Sunset"); got != sunset.Format(http.TimeFormat) {
+		t.Fatalf("expected Sunset header, got %q", got)
+	}
+	if got := rec.Header().Get("Link"); got != `<https://example.com/changelog>; rel="deprecation"` {
+		t.Fatalf("expected Link header, got %q", got)
+	}
+}
+
+func TestMiddlewareRejectsParseError(t *testing.T) {
+	vm := &VersionManager{}
+	vm.Add(&Version{Date: "2017-01-02"})
+
+	handler := vm.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		t.Fatal("next should not be called when parsing fails")
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,373 @@
+// Package pinned implements date- and version-pinned API versioning in the
+// style of Stripe: clients pin to a version when they integrate, and the
+// server migrates each request and response between that version and the
+// current one using a chain of registered changes.
+package pinned
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrNoVersionSupplied is returned when a request does not specify a version.
+	ErrNoVersionSupplied = errors.New("pinned: no version supplied")
+	// ErrInvalidVersion is returned when a request specifies a version that does not exist.
+	ErrInvalidVersion = errors.New("pinned: invalid version")
+	// ErrVersionDeprecated is returned when a request specifies a version that has been deprecated.
+	ErrVersionDeprecated = errors.New("pinned: version deprecated")
+)
+
+// Version represents a single API version and the changes it introduces
+// relative to the version before it.
+type Version struct {
+	// Date is the version string, e.g. "2018-01-02" for the default
+	// DateScheme. Its format is determined by the owning VersionManager's
+	// VersionScheme.
+	Date string
+
+	// Deprecated marks a version as deprecated. Deprecated versions stay
+	// registered (VersionManager.Apply still migrates through their
+	// Changes), but VersionManager.Parse rejects requests that pin to them.
+	Deprecated bool
+
+	// DeprecatedAt and SunsetAt, if set, mark a version as being in a
+	// softer deprecation window: still served by VersionManager.Parse, but
+	// advertised via the RFC 8594 Deprecation and Sunset response headers
+	// by VersionManager.Middleware. Unlike Deprecated, these don't block
+	// the request.
+	DeprecatedAt time.Time
+	SunsetAt     time.Time
+
+	// Changes are the transformations introduced by this version, applied
+	// in order when migrating a response down to an older version.
+	Changes []*Change
+}
+
+// Change describes a single behavioral change introduced in a Version.
+type Change struct {
+	// Description documents the change for changelog generation.
+	Description string
+
+	// Actions maps a type name (see Object) to the Action that migrates
+	// it across this Change.
+	Actions map[string]Action
+}
+
+// Action migrates the map representation of an Object across a single
+// Change. Down migrates a current-schema representation backward, for
+// responding to clients pinned to the version before the Change (this is
+// the behavior VersionManager.Apply has always provided). Up migrates an
+// old client's inbound request body forward to the current schema, for
+// VersionManager.ApplyInbound. Either may be nil if the Change doesn't
+// affect that direction.
+type Action struct {
+	Down func(map[string]interface{}) map[string]interface{}
+	Up   func(map[string]interface{}) map[string]interface{}
+}
+
+// Object is implemented by types that can be migrated between versions by
+// VersionManager.Apply.
+type Object interface {
+	Data() map[string]interface{}
+}
+
+// VersionManager tracks the set of known API versions and migrates
+// responses between them.
+//
+// A VersionManager is safe for concurrent use: all of its methods,
+// including Add, Deprecate, Versions, Latest, Parse, ParseWithCurrent,
+// Apply, ApplyInbound, Changelog, ChangelogHandler, Middleware, and
+// ChangelogURL/SetChangelogURL, may be called from multiple goroutines.
+// That guarantee covers access through the manager only: a *Version handed
+// back by one of these methods shares the manager's lock for its
+// DeprecatedAt/SunsetAt fields (mutated only by Deprecate) as long as those
+// fields are read via VersionManager methods such as Middleware. Reading
+// them directly off a stored *Version outside of such a call is not
+// synchronized against a concurrent Deprecate.
+type VersionManager struct {
+	// Scheme determines how version strings are parsed, validated, and
+	// compared. It defaults to DateScheme, preserving the original
+	// "2006-01-02" date-based behavior.
+	Scheme VersionScheme
+
+	// MediaType, if configured, lets VersionManager.Parse also resolve a
+	// version from the Accept header's "version" parameter.
+	MediaType MediaType
+
+	// Default is returned by VersionManager.Parse when a request supplies
+	// no version by any means. If nil, Parse returns ErrNoVersionSupplied
+	// instead.
+	Default *Version
+
+	mu           sync.RWMutex
+	versions     []*Version
+	changelogURL string
+}
+
+// scheme returns the manager's configured VersionScheme, falling back to
+// DateScheme when none has been set.
+func (vm *VersionManager) scheme() VersionScheme {
+	if vm.Scheme == nil {
+		return DateScheme{}
+	}
+	return vm.Scheme
+}
+
+// Add registers a new version with the manager. It returns an error if the
+// version's Date does not match the manager's VersionScheme.
+func (vm *VersionManager) Add(v *Version) error {
+	if err := vm.scheme().Validate(v.Date); err != nil {
+		return err
+	}
+
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	vm.versions = append(vm.versions, v)
+	vm.sortVersions()
+	return nil
+}
+
+// Deprecate marks the registered version with the given date as entering
+// its deprecation window: it sets DeprecatedAt to now and SunsetAt to
+// sunset, so Middleware advertises it via the RFC 8594 Deprecation and
+// Sunset headers. It does not set the Deprecated flag, so the version
+// continues to be served; use that field directly (before registering
+// future versions) to hard-block a version instead. It returns
+// ErrInvalidVersion if no version with that date is registered.
+func (vm *VersionManager) Deprecate(date string, sunset time.Time) error {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	v := vm.find(date)
+	if v == nil {
+		return ErrInvalidVersion
+	}
+	v.DeprecatedAt = time.Now()
+	v.SunsetAt = sunset
+	return nil
+}
+
+// sortVersions sorts the registered versions newest first, according to the
+// manager's VersionScheme.
+func (vm *VersionManager) sortVersions() {
+	s := vm.scheme()
+	sort.Slice(vm.versions, func(i, j int) bool {
+		a, errA := s.Parse(vm.versions[i].Date)
+		b, errB := s.Parse(vm.versions[j].Date)
+		if errA != nil || errB != nil {
+			return vm.versions[i].Date > vm.versions[j].Date
+		}
+		return s.Compare(a, b) > 0
+	})
+}
+
+// Versions returns the date strings of all registered versions, newest
+// first.
+func (vm *VersionManager) Versions() []string {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	dates := make([]string, len(vm.versions))
+	for i, v := range vm.versions {
+		dates[i] = v.Date
+	}
+	return dates
+}
+
+// Latest returns the newest registered version according to the manager's
+// VersionScheme, or nil if none have been added.
+func (vm *VersionManager) Latest() *Version {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	if len(vm.versions) == 0 {
+		return nil
+	}
+	return vm.versions[0]
+}
+
+// find returns the registered version with the given date, or nil if no
+// such version exists.
+func (vm *VersionManager) find(date string) *Version {
+	for _, v := range vm.versions {
+		if v.Date == date {
+			return v
+		}
+	}
+	return nil
+}
+
+// resolve finds the version matching s, resolving partial version strings
+// (e.g. "v1" under SemVerScheme) to the newest matching registered version.
+func (vm *VersionManager) resolve(s string) *Version {
+	if v := vm.find(s); v != nil {
+		return v
+	}
+
+	resolver, ok := vm.scheme().(PartialResolver)
+	if !ok {
+		return nil
+	}
+
+	var best *Version
+	var bestComparable Comparable
+	scheme := vm.scheme()
+	for _, v := range vm.versions {
+		full, err := scheme.Parse(v.Date)
+		if err != nil {
+			continue
+		}
+		if !resolver.Matches(s, full) {
+			continue
+		}
+		if best == nil || scheme.Compare(full, bestComparable) > 0 {
+			best = v
+			bestComparable = full
+		}
+	}
+	return best
+}
+
+// Parse determines which version a request targets. It checks, in order of
+// precedence, the "v" query parameter, the "Version" header, and the
+// "version" parameter of a vendor media type in the Accept header (see
+// MediaType); the first of these present wins. If none are present, it
+// returns vm.Default if set, or ErrNoVersionSupplied otherwise. It returns
+// ErrInvalidVersion if the version string doesn't match a registered
+// version, and ErrVersionDeprecated if it resolves to a deprecated
+// version.
+//
+// Parse also recognizes the alias strings "latest" and "upgrade" (see
+// ParseWithCurrent for "patch" and downgrade protection, which additionally
+// require a current version).
+func (vm *VersionManager) Parse(req *http.Request) (*Version, error) {
+	return vm.parse(req, nil)
+}
+
+// ParseWithCurrent is like Parse, but also accepts the caller's current
+// version, which is used to resolve the "patch" alias and to guard against
+// the "latest" and "patch" aliases resolving to a version older than
+// current; it returns ErrDowngradeBlocked in that case. The "upgrade" alias
+// instead returns current itself when current is not older than latest.
+func (vm *VersionManager) ParseWithCurrent(req *http.Request, current *Version) (*Version, error) {
+	return vm.parse(req, current)
+}
+
+func (vm *VersionManager) parse(req *http.Request, current *Version) (*Version, error) {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	s, ok := vm.requestedVersion(req)
+	if !ok {
+		if vm.Default != nil {
+			return vm.Default, nil
+		}
+		return nil, ErrNoVersionSupplied
+	}
+
+	v, err := vm.resolveCandidate(s, current)
+	if err != nil {
+		return nil, err
+	}
+
+	if v.Deprecated {
+		return nil, ErrVersionDeprecated
+	}
+	return v, nil
+}
+
+// requestedVersion returns the version string req specifies, preferring
+// the "v" query parameter, then the "Version" header, then the Accept
+// header's media type version parameter, in that order.
+func (vm *VersionManager) requestedVersion(req *http.Request) (string, bool) {
+	if q := req.URL.Query().Get("v"); q != "" {
+		return q, true
+	}
+	if h := req.Header.Get("Version"); h != "" {
+		return h, true
+	}
+	if v, ok := vm.MediaType.parse(req.Header.Get("Accept")); ok {
+		return v, true
+	}
+	return "", false
+}
+
+// Apply migrates obj's Data() down from the current schema to the schema in
+// effect at v, by applying the Action registered for obj's type in every
+// Change introduced after v, newest first.
+func (vm *VersionManager) Apply(v *Version, obj Object) (map[string]interface{}, error) {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	m := obj.Data()
+	name := typeName(obj)
+
+	scheme := vm.scheme()
+	target, err := scheme.Parse(v.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ver := range vm.versions {
+		comparable, err := scheme.Parse(ver.Date)
+		if err != nil {
+			continue
+		}
+		if scheme.Compare(comparable, target) <= 0 {
+			break
+		}
+		for _, change := range ver.Changes {
+			if action, ok := change.Actions[name]; ok && action.Down != nil {
+				m = action.Down(m)
+			}
+		}
+	}
+	return m, nil
+}
+
+// ApplyInbound migrates m, an inbound request body shaped for the version
+// v an old client is pinned to, forward to the current schema, by applying
+// the Action registered for typeName in every Change introduced after v,
+// oldest first.
+func (vm *VersionManager) ApplyInbound(v *Version, typeName string, m map[string]interface{}) (map[string]interface{}, error) {
+	vm.mu.RLock()
+	defer vm.mu.RUnlock()
+
+	scheme := vm.scheme()
+	target, err := scheme.Parse(v.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := len(vm.versions) - 1; i >= 0; i-- {
+		ver := vm.versions[i]
+		comparable, err := scheme.Parse(ver.Date)
+		if err != nil {
+			continue
+		}
+		if scheme.Compare(comparable, target) <= 0 {
+			continue
+		}
+		for _, change := range ver.Changes {
+			if action, ok := change.Actions[typeName]; ok && action.Up != nil {
+				m = action.Up(m)
+			}
+		}
+	}
+	return m, nil
+}
+
+// typeName returns the unqualified type name of obj, unwrapping a single
+// level of pointer indirection.
+func typeName(obj Object) string {
+	t := reflect.TypeOf(obj)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Name()
+}
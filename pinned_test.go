@@ -5,7 +5,9 @@ import (
 	"log"
 	"net/http"
 	"net/http/httptest"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestVersionManagerAdd(t *testing.T) {
@@ -107,7 +109,7 @@ func TestVersionManagerParse(t *testing.T) {
 		t.Fatalf("Expected no error, instead got %s", err)
 	}
 
-	// Should select more recent version if supplied in query params and header.
+	// The query param takes precedence over the header when both are supplied.
 	newV := &Version{
 		Date: "2018-01-02",
 	}
@@ -119,8 +121,8 @@ func TestVersionManagerParse(t *testing.T) {
 	if err != nil {
 		t.Fatalf("Expected no error, instead got %s", err)
 	}
-	if v.Date != "2018-01-02" {
-		t.Fatalf("Expected version 2018-01-02, instead got %s", v.Date)
+	if v.Date != "2017-01-02" {
+		t.Fatalf("Expected version 2017-01-02, instead got %s", v.Date)
 	}
 
 	// Should fail if version is deprecated.
@@ -162,7 +164,7 @@ func TestVersionManagerApply(t *testing.T) {
 			{
 				Description: "Foobar.",
 				Actions: map[string]Action{
-					"TestObject": action,
+					"TestObject": {Down: action},
 				},
 			},
 		},
@@ -179,3 +181,120 @@ func TestVersionManagerApply(t *testing.T) {
 		t.Fatalf("Expected map[A] = Foo, instead got %s", res["A"].(string))
 	}
 }
+
+func TestVersionManagerApplyInbound(t *testing.T) {
+	vm := &VersionManager{}
+
+	up := func(m map[string]interface{}) map[string]interface{} {
+		m["B"] = m["A"]
+		delete(m, "A")
+		return m
+	}
+
+	version := &Version{
+		Date: "2017-01-02",
+	}
+	vm.Add(version)
+
+	vm.Add(&Version{
+		Date: "2018-01-02",
+		Changes: []*Change{
+			{
+				Description: "Foobar.",
+				Actions: map[string]Action{
+					"TestObject": {Up: up},
+				},
+			},
+		},
+	})
+
+	res, err := vm.ApplyInbound(version, "TestObject", map[string]interface{}{"A": "Foo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res["B"].(string) != "Foo" {
+		t.Fatalf("Expected map[B] = Foo, instead got %s", res["B"].(string))
+	}
+}
+
+func TestVersionManagerDeprecate(t *testing.T) {
+	vm := &VersionManager{}
+	vm.Add(&Version{Date: "2017-01-02"})
+
+	if err := vm.Deprecate("2099-01-02", time.Time{}); err != ErrInvalidVersion {
+		t.Fatalf("Expected ErrInvalidVersion, instead got %s", err)
+	}
+
+	sunset := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if err := vm.Deprecate("2017-01-02", sunset); err != nil {
+		t.Fatal(err)
+	}
+
+	v := vm.Latest()
+	if v.DeprecatedAt.IsZero() {
+		t.Fatal("Expected DeprecatedAt to be set")
+	}
+	if !v.SunsetAt.Equal(sunset) {
+		t.Fatalf("Expected SunsetAt %s, instead got %s", sunset, v.SunsetAt)
+	}
+
+	// Deprecate only sets the soft deprecation window; the version should
+	// still be servable.
+	req := httptest.NewRequest(http.MethodGet, "/users?v=2017-01-02", nil)
+	if _, err := vm.Parse(req); err != nil {
+		t.Fatalf("Expected no error, instead got %s", err)
+	}
+}
+
+func TestVersionManagerConcurrentAccess(t *testing.T) {
+	vm := &VersionManager{}
+	vm.Add(&Version{Date: "2017-01-02"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			vm.Add(&Version{Date: fmt.Sprintf("20%02d-01-02", i)})
+		}(i)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users?v=2017-01-02", nil)
+	handler := vm.Middleware(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}))
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vm.Versions()
+			vm.Latest()
+			vm.Parse(req)
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+		}()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			vm.Deprecate("2017-01-02", time.Now())
+			vm.SetChangelogURL("https://example.com/changelog")
+		}()
+	}
+	wg.Wait()
+}
+
+func BenchmarkVersionManagerParseConcurrent(b *testing.B) {
+	vm := &VersionManager{}
+	for i := 0; i < 50; i++ {
+		vm.Add(&Version{Date: fmt.Sprintf("20%02d-01-02", i)})
+	}
+	req := httptest.NewRequest(http.MethodGet, "/users?v=2049-01-02", nil)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := vm.Parse(req); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,149 @@
+package pinned
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// dateFormat is the layout used by DateScheme, matching Stripe's
+// "2006-01-02" convention.
+const dateFormat = "2006-01-02"
+
+// Comparable is an opaque, scheme-specific representation of a parsed
+// version string, produced by VersionScheme.Parse and consumed by
+// VersionScheme.Compare.
+type Comparable interface{}
+
+// VersionScheme determines how a VersionManager parses, validates, and
+// orders version strings. The default is DateScheme; SemVerScheme is
+// provided for APIs that prefer semantic-version-style pinning.
+type VersionScheme interface {
+	// Parse converts a version string into a Comparable value.
+	Parse(s string) (Comparable, error)
+
+	// Compare returns a negative number if a < b, zero if a == b, and a
+	// positive number if a > b.
+	Compare(a, b Comparable) int
+
+	// Validate returns an error if s is not a valid version string for
+	// this scheme.
+	Validate(s string) error
+}
+
+// PartialResolver is implemented by VersionSchemes that support resolving a
+// partial version string (e.g. "v1") to the newest registered version it
+// matches (e.g. the highest "v1.x.y"). VersionManager.Parse consults it
+// when an exact match isn't found.
+type PartialResolver interface {
+	// Matches reports whether the partial version string s identifies the
+	// fully-specified version full.
+	Matches(s string, full Comparable) bool
+}
+
+// DateScheme is the original pinned VersionScheme: versions are
+// "2006-01-02" date strings, ordered chronologically.
+type DateScheme struct{}
+
+// Validate implements VersionScheme.
+func (DateScheme) Validate(s string) error {
+	_, err := time.Parse(dateFormat, s)
+	return err
+}
+
+// Parse implements VersionScheme.
+func (DateScheme) Parse(s string) (Comparable, error) {
+	return time.Parse(dateFormat, s)
+}
+
+// Compare implements VersionScheme.
+func (DateScheme) Compare(a, b Comparable) int {
+	ta, tb := a.(time.Time), b.(time.Time)
+	switch {
+	case ta.Before(tb):
+		return -1
+	case ta.After(tb):
+		return 1
+	default:
+		return 0
+	}
+}
+
+// semVer is the Comparable produced by SemVerScheme. HasMinor and HasPatch
+// track whether the original string specified those components, so that
+// partial versions like "v1" can be resolved to a concrete release without
+// being confused with "v1.0.0".
+type semVer struct {
+	Major, Minor, Patch int
+	HasMinor, HasPatch  bool
+}
+
+var semVerPattern = regexp.MustCompile(`^v(\d+)(?:\.(\d+)(?:\.(\d+))?)?$`)
+
+// SemVerScheme is a VersionScheme accepting "v1", "v1.2", and "v1.2.3"
+// forms, the same partial-version routing used by gopkg.in. Versions
+// registered with VersionManager.Add should be fully specified
+// ("v1.2.3"); the partial forms are for resolving client-supplied version
+// strings via VersionManager.Parse.
+type SemVerScheme struct{}
+
+// Validate implements VersionScheme.
+func (SemVerScheme) Validate(s string) error {
+	if !semVerPattern.MatchString(s) {
+		return fmt.Errorf("pinned: invalid semver %q", s)
+	}
+	return nil
+}
+
+// Parse implements VersionScheme.
+func (SemVerScheme) Parse(s string) (Comparable, error) {
+	m := semVerPattern.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("pinned: invalid semver %q", s)
+	}
+
+	v := semVer{}
+	v.Major, _ = strconv.Atoi(m[1])
+	if m[2] != "" {
+		v.HasMinor = true
+		v.Minor, _ = strconv.Atoi(m[2])
+	}
+	if m[3] != "" {
+		v.HasPatch = true
+		v.Patch, _ = strconv.Atoi(m[3])
+	}
+	return v, nil
+}
+
+// Compare implements VersionScheme.
+func (SemVerScheme) Compare(a, b Comparable) int {
+	va, vb := a.(semVer), b.(semVer)
+	if va.Major != vb.Major {
+		return va.Major - vb.Major
+	}
+	if va.Minor != vb.Minor {
+		return va.Minor - vb.Minor
+	}
+	return va.Patch - vb.Patch
+}
+
+// Matches implements PartialResolver, treating an unset component in s as a
+// wildcard: "v1" matches any "v1.x.y", and "v1.2" matches any "v1.2.x".
+func (SemVerScheme) Matches(s string, full Comparable) bool {
+	partial, err := SemVerScheme{}.Parse(s)
+	if err != nil {
+		return false
+	}
+	p, f := partial.(semVer), full.(semVer)
+	if p.Major != f.Major {
+		return false
+	}
+	if p.HasMinor && p.Minor != f.Minor {
+		return false
+	}
+	if p.HasPatch && p.Patch != f.Patch {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,67 @@
+package pinned
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSemVerSchemeValidate(t *testing.T) {
+	s := SemVerScheme{}
+
+	for _, v := range []string{"v1", "v1.2", "v1.2.3"} {
+		if err := s.Validate(v); err != nil {
+			t.Fatalf("expected %q to be valid, got %s", v, err)
+		}
+	}
+
+	for _, v := range []string{"1.2.3", "v", "va.b.c", "2018-01-02"} {
+		if err := s.Validate(v); err == nil {
+			t.Fatalf("expected %q to be invalid", v)
+		}
+	}
+}
+
+func TestSemVerSchemeCompare(t *testing.T) {
+	s := SemVerScheme{}
+
+	older, _ := s.Parse("v1.2.3")
+	newer, _ := s.Parse("v1.10.0")
+	if s.Compare(older, newer) >= 0 {
+		t.Fatal("expected v1.2.3 < v1.10.0")
+	}
+	if s.Compare(newer, older) <= 0 {
+		t.Fatal("expected v1.10.0 > v1.2.3")
+	}
+
+	same, _ := s.Parse("v1.2.3")
+	if s.Compare(older, same) != 0 {
+		t.Fatal("expected v1.2.3 == v1.2.3")
+	}
+}
+
+func TestVersionManagerSemVerScheme(t *testing.T) {
+	vm := &VersionManager{Scheme: SemVerScheme{}}
+
+	if err := vm.Add(&Version{Date: "2018-01-02"}); err == nil {
+		t.Fatal("expected error when adding date-style version under SemVerScheme")
+	}
+
+	vm.Add(&Version{Date: "v1.0.0"})
+	vm.Add(&Version{Date: "v1.2.0"})
+	vm.Add(&Version{Date: "v2.0.0"})
+
+	versions := vm.Versions()
+	if versions[0] != "v2.0.0" || versions[1] != "v1.2.0" || versions[2] != "v1.0.0" {
+		t.Fatalf("expected versions sorted descending, got %v", versions)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/users?v=v1", nil)
+	v, err := vm.Parse(req)
+	if err != nil {
+		t.Fatalf("expected no error, got %s", err)
+	}
+	if v.Date != "v1.2.0" {
+		t.Fatalf("expected partial \"v1\" to resolve to v1.2.0, got %s", v.Date)
+	}
+}